@@ -0,0 +1,208 @@
+package grpcmw
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testServer starts a health.Server behind a bufconn listener with the given
+// interceptors, and returns a client dialed to it plus a shutdown func.
+func testServer(t *testing.T, unary grpc.UnaryServerInterceptor, stream grpc.StreamServerInterceptor) healthpb.HealthClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(unary),
+		grpc.StreamInterceptor(stream),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryServerLogging_Bufconn(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	client := testServer(t,
+		UnaryServerLogging(WithLogger(logger)),
+		StreamServerLogging(WithLogger(logger)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Check status = %v, want SERVING", resp.Status)
+	}
+
+	entries := logs.FilterMessage("grpc server call").All()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 'grpc server call' log entry, got %d", len(entries))
+	}
+	if got := entries[0].ContextMap()["grpc.method"]; got != "Check" {
+		t.Errorf("grpc.method field = %v, want Check", got)
+	}
+}
+
+func TestUnaryServerRecovery_Bufconn(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	panicky := func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		panic("boom")
+	}
+
+	client := testServer(t,
+		ChainUnaryServer(UnaryServerRecovery(WithLogger(logger)), panicky),
+		StreamServerLogging(WithLogger(logger)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err == nil {
+		t.Fatal("expected Check to return an error after the handler panicked")
+	}
+	if st, _ := status.FromError(err); st.Code() != codes.Internal {
+		t.Errorf("Check error code = %v, want Internal", st.Code())
+	}
+
+	entries := logs.FilterMessage("panic recovered in grpc unary server").All()
+	if n := len(entries); n != 1 {
+		t.Fatalf("expected 1 panic log entry, got %d", n)
+	}
+
+	// The first captured frame must be the function that actually panicked,
+	// not runtime.gopanic or the recovery middleware's own deferred closure -
+	// regression check for the stacktrace.Field skip count.
+	stack, _ := entries[0].ContextMap()["stack"].([]interface{})
+	if len(stack) == 0 {
+		t.Fatal("expected a non-empty stack field")
+	}
+	first, _ := stack[0].(map[string]interface{})
+	if fn, _ := first["function"].(string); strings.Contains(fn, "runtime.gopanic") {
+		t.Errorf("stack[0].function = %q, should skip past runtime.gopanic to the panicking function", fn)
+	}
+}
+
+func TestStreamServerLogging_Bufconn(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	client := testServer(t,
+		UnaryServerLogging(WithLogger(logger)),
+		StreamServerLogging(WithLogger(logger)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ws, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	resp, err := ws.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("Watch status = %v, want SERVING", resp.Status)
+	}
+	cancel()
+	_, err = ws.Recv()
+	if err == nil {
+		t.Fatal("expected Recv to fail once the call context is canceled")
+	}
+
+	// The server-side interceptor only logs once its handler returns, which
+	// happens asynchronously relative to the client observing the canceled
+	// context, so poll briefly instead of asserting immediately.
+	var entries []observer.LoggedEntry
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		entries = logs.FilterMessageSnippet("grpc server stream").All()
+		if len(entries) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 'grpc server stream' log entry, got %d", len(entries))
+	}
+	if got := entries[0].ContextMap()["grpc.method"]; got != "Watch" {
+		t.Errorf("grpc.method field = %v, want Watch", got)
+	}
+}
+
+func TestStreamServerRecovery_Bufconn(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	panicky := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		panic("boom")
+	}
+
+	client := testServer(t,
+		UnaryServerLogging(WithLogger(logger)),
+		ChainStreamServer(StreamServerRecovery(WithLogger(logger)), panicky),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ws, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	_, err = ws.Recv()
+	if err == nil || err == io.EOF {
+		t.Fatalf("expected Recv to surface the recovered panic as an error, got %v", err)
+	}
+	if st, _ := status.FromError(err); st.Code() != codes.Internal {
+		t.Errorf("Recv error code = %v, want Internal", st.Code())
+	}
+
+	if n := len(logs.FilterMessage("panic recovered in grpc stream server").All()); n != 1 {
+		t.Fatalf("expected 1 panic log entry, got %d", n)
+	}
+}
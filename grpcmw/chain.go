@@ -0,0 +1,104 @@
+package grpcmw
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// ChainUnaryClient composes multiple unary client interceptors into a single
+// one. They are invoked outer-to-inner in the order passed in, i.e.
+// ChainUnaryClient(a, b, c) behaves as a(b(c(invoker))).
+func ChainUnaryClient(interceptors ...grpc.UnaryClientInterceptor) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		chained := invoker
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			chained = chainUnaryInvoker(interceptors[i], chained)
+		}
+		return chained(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func chainUnaryInvoker(curr grpc.UnaryClientInterceptor, next grpc.UnaryInvoker) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return curr(ctx, method, req, reply, cc, next, opts...)
+	}
+}
+
+// ChainStreamClient composes multiple streaming client interceptors into a
+// single one, invoked outer-to-inner in the order passed in.
+func ChainStreamClient(interceptors ...grpc.StreamClientInterceptor) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		chained := streamer
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			chained = chainStreamer(interceptors[i], chained)
+		}
+		return chained(ctx, desc, cc, method, opts...)
+	}
+}
+
+func chainStreamer(curr grpc.StreamClientInterceptor, next grpc.Streamer) grpc.Streamer {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return curr(ctx, desc, cc, method, next, opts...)
+	}
+}
+
+// ChainUnaryServer composes multiple unary server interceptors into a
+// single one, invoked outer-to-inner in the order passed in.
+func ChainUnaryServer(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			chained = chainUnaryHandler(interceptors[i], info, chained)
+		}
+		return chained(ctx, req)
+	}
+}
+
+func chainUnaryHandler(curr grpc.UnaryServerInterceptor, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) grpc.UnaryHandler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		return curr(ctx, req, info, next)
+	}
+}
+
+// ChainStreamServer composes multiple streaming server interceptors into a
+// single one, invoked outer-to-inner in the order passed in.
+func ChainStreamServer(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			chained = chainStreamHandler(interceptors[i], info, chained)
+		}
+		return chained(srv, ss)
+	}
+}
+
+func chainStreamHandler(curr grpc.StreamServerInterceptor, info *grpc.StreamServerInfo, next grpc.StreamHandler) grpc.StreamHandler {
+	return func(srv interface{}, ss grpc.ServerStream) error {
+		return curr(srv, ss, info, next)
+	}
+}
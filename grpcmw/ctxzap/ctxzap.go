@@ -0,0 +1,31 @@
+// Package ctxzap attaches a request-scoped zap.Logger to a context.Context
+// so that interceptors can hand their logger down to handlers, and handlers
+// can enrich it with their own fields without needing a reference to the
+// interceptor's logger.
+package ctxzap
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type ctxMarker struct{}
+
+var ctxMarkerKey = ctxMarker{}
+
+// ToContext returns a copy of ctx carrying logger, retrievable via Extract.
+func ToContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxMarkerKey, logger)
+}
+
+// Extract returns the zap.Logger previously attached to ctx via ToContext.
+// If none is present, it returns zap.NewNop() so callers can log
+// unconditionally.
+func Extract(ctx context.Context) *zap.Logger {
+	logger, ok := ctx.Value(ctxMarkerKey).(*zap.Logger)
+	if !ok || logger == nil {
+		return zap.NewNop()
+	}
+	return logger
+}
@@ -2,13 +2,15 @@ package grpcmw
 
 import (
 	"context"
-	"runtime/debug"
 	"time"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/technothecow/termine-go-utils/internal/stacktrace"
+	"github.com/technothecow/termine-go-utils/tracing"
 )
 
 // UnaryClientLogging returns a gRPC unary client interceptor that logs
@@ -18,12 +20,10 @@ import (
 //   - grpc_type: "unary"
 //   - grpc_method
 //   - grpc_code
-//   - duration
+//   - duration (unless WithDurationField(false))
 //   - error (when non-nil)
-func UnaryClientLogging(logger *zap.Logger) grpc.UnaryClientInterceptor {
-	if logger == nil {
-		logger = zap.NewNop()
-	}
+func UnaryClientLogging(opts ...Option) grpc.UnaryClientInterceptor {
+	o := newOptions(opts...)
 
 	return func(
 		ctx context.Context,
@@ -31,11 +31,14 @@ func UnaryClientLogging(logger *zap.Logger) grpc.UnaryClientInterceptor {
 		req, reply interface{},
 		cc *grpc.ClientConn,
 		invoker grpc.UnaryInvoker,
-		opts ...grpc.CallOption,
+		callOpts ...grpc.CallOption,
 	) error {
+		if o.skipper != nil && o.skipper(ctx, method) {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		}
+
 		start := time.Now()
-		err := invoker(ctx, method, req, reply, cc, opts...)
-		dur := time.Since(start)
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
 
 		st, _ := status.FromError(err)
 		code := codes.OK
@@ -47,26 +50,33 @@ func UnaryClientLogging(logger *zap.Logger) grpc.UnaryClientInterceptor {
 			zap.String("grpc_type", "unary"),
 			zap.String("grpc_method", method),
 			zap.String("grpc_code", code.String()),
-			zap.Duration("duration", dur),
+		}
+		if o.logDuration {
+			fields = append(fields, zap.Duration("duration", time.Since(start)))
+		}
+		if o.fieldExtractor != nil {
+			fields = append(fields, o.fieldExtractor(ctx)...)
+		}
+		fields = append(fields, tracing.SpanFields(ctx)...)
+		if o.payloadLogging {
+			fields = append(fields, payloadFields(req, reply)...)
 		}
 
+		msg := "grpc client call"
 		if err != nil {
 			fields = append(fields, zap.Error(err))
-			logger.Error("grpc client call failed", fields...)
-			return err
+			msg = "grpc client call failed"
 		}
+		logAt(o.logger, o.levelFunc(code), msg, fields...)
 
-		logger.Info("grpc client call", fields...)
-		return nil
+		return err
 	}
 }
 
 // StreamClientLogging returns a gRPC streaming client interceptor that logs
 // outgoing streaming RPCs and their results using zap.
-func StreamClientLogging(logger *zap.Logger) grpc.StreamClientInterceptor {
-	if logger == nil {
-		logger = zap.NewNop()
-	}
+func StreamClientLogging(opts ...Option) grpc.StreamClientInterceptor {
+	o := newOptions(opts...)
 
 	return func(
 		ctx context.Context,
@@ -74,11 +84,14 @@ func StreamClientLogging(logger *zap.Logger) grpc.StreamClientInterceptor {
 		cc *grpc.ClientConn,
 		method string,
 		streamer grpc.Streamer,
-		opts ...grpc.CallOption,
+		callOpts ...grpc.CallOption,
 	) (grpc.ClientStream, error) {
+		if o.skipper != nil && o.skipper(ctx, method) {
+			return streamer(ctx, desc, cc, method, callOpts...)
+		}
+
 		start := time.Now()
-		clientStream, err := streamer(ctx, desc, cc, method, opts...)
-		dur := time.Since(start)
+		clientStream, err := streamer(ctx, desc, cc, method, callOpts...)
 
 		st, _ := status.FromError(err)
 		code := codes.OK
@@ -90,26 +103,33 @@ func StreamClientLogging(logger *zap.Logger) grpc.StreamClientInterceptor {
 			zap.String("grpc_type", "stream"),
 			zap.String("grpc_method", method),
 			zap.String("grpc_code", code.String()),
-			zap.Duration("duration", dur),
 		}
+		if o.logDuration {
+			fields = append(fields, zap.Duration("duration", time.Since(start)))
+		}
+		if o.fieldExtractor != nil {
+			fields = append(fields, o.fieldExtractor(ctx)...)
+		}
+		fields = append(fields, tracing.SpanFields(ctx)...)
 
+		msg := "grpc client stream"
 		if err != nil {
 			fields = append(fields, zap.Error(err))
-			logger.Error("grpc client stream failed", fields...)
-			return nil, err
+			msg = "grpc client stream failed"
 		}
+		logAt(o.logger, o.levelFunc(code), msg, fields...)
 
-		logger.Info("grpc client stream", fields...)
+		if err != nil {
+			return nil, err
+		}
 		return clientStream, nil
 	}
 }
 
 // UnaryClientRecovery returns a gRPC unary client interceptor that converts
 // panics into gRPC errors and logs them with stack traces.
-func UnaryClientRecovery(logger *zap.Logger) grpc.UnaryClientInterceptor {
-	if logger == nil {
-		logger = zap.NewNop()
-	}
+func UnaryClientRecovery(opts ...Option) grpc.UnaryClientInterceptor {
+	o := newOptions(opts...)
 
 	return func(
 		ctx context.Context,
@@ -117,30 +137,30 @@ func UnaryClientRecovery(logger *zap.Logger) grpc.UnaryClientInterceptor {
 		req, reply interface{},
 		cc *grpc.ClientConn,
 		invoker grpc.UnaryInvoker,
-		opts ...grpc.CallOption,
+		callOpts ...grpc.CallOption,
 	) (err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				stack := debug.Stack()
-				logger.Error("panic recovered in grpc unary client",
+				o.logger.Error("panic recovered in grpc unary client",
 					zap.String("grpc_method", method),
 					zap.Any("panic", r),
-					zap.ByteString("stack", stack),
+					stacktrace.Field(4),
 				)
-				err = status.Errorf(codes.Internal, "internal client panic")
+				if o.panicCounter != nil {
+					o.panicCounter.Inc()
+				}
+				err = o.recoveryHandler(ctx, r)
 			}
 		}()
 
-		return invoker(ctx, method, req, reply, cc, opts...)
+		return invoker(ctx, method, req, reply, cc, callOpts...)
 	}
 }
 
-// StreamClientRecovery returns a gRPC streaming client interceptor that converts
-// panics into gRPC errors and logs them with stack traces.
-func StreamClientRecovery(logger *zap.Logger) grpc.StreamClientInterceptor {
-	if logger == nil {
-		logger = zap.NewNop()
-	}
+// StreamClientRecovery returns a gRPC streaming client interceptor that
+// converts panics into gRPC errors and logs them with stack traces.
+func StreamClientRecovery(opts ...Option) grpc.StreamClientInterceptor {
+	o := newOptions(opts...)
 
 	return func(
 		ctx context.Context,
@@ -148,21 +168,23 @@ func StreamClientRecovery(logger *zap.Logger) grpc.StreamClientInterceptor {
 		cc *grpc.ClientConn,
 		method string,
 		streamer grpc.Streamer,
-		opts ...grpc.CallOption,
+		callOpts ...grpc.CallOption,
 	) (clientStream grpc.ClientStream, err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				stack := debug.Stack()
-				logger.Error("panic recovered in grpc stream client",
+				o.logger.Error("panic recovered in grpc stream client",
 					zap.String("grpc_method", method),
 					zap.Any("panic", r),
-					zap.ByteString("stack", stack),
+					stacktrace.Field(4),
 				)
-				err = status.Errorf(codes.Internal, "internal client panic")
+				if o.panicCounter != nil {
+					o.panicCounter.Inc()
+				}
+				err = o.recoveryHandler(ctx, r)
 				clientStream = nil
 			}
 		}()
 
-		return streamer(ctx, desc, cc, method, opts...)
+		return streamer(ctx, desc, cc, method, callOpts...)
 	}
 }
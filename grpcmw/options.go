@@ -0,0 +1,123 @@
+package grpcmw
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+)
+
+// FieldExtractorFunc derives additional zap fields from the request
+// context, e.g. to surface a request ID or tenant stashed there by an
+// earlier interceptor.
+type FieldExtractorFunc func(ctx context.Context) []zap.Field
+
+// SkipperFunc reports whether logging instrumentation should be skipped for
+// the given method, e.g. to silence health checks.
+type SkipperFunc func(ctx context.Context, fullMethod string) bool
+
+// LevelFunc maps a finished call's status code to the zap level it should be
+// logged at. The default logs codes.OK at Info and everything else at
+// Error.
+type LevelFunc func(code codes.Code) zapcore.Level
+
+// Option configures the logging and recovery interceptors in this package.
+type Option func(*options)
+
+type options struct {
+	logger          *zap.Logger
+	levelFunc       LevelFunc
+	logDuration     bool
+	fieldExtractor  FieldExtractorFunc
+	payloadLogging  bool
+	skipper         SkipperFunc
+	recoveryHandler RecoveryHandlerFunc
+	panicCounter    prometheus.Counter
+
+	retryMax            uint
+	retryPerCallTimeout time.Duration
+	retryBackoff        BackoffFunc
+	retryCodes          []codes.Code
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		logger:          zap.NewNop(),
+		levelFunc:       defaultLevelFunc,
+		logDuration:     true,
+		recoveryHandler: defaultRecoveryHandler,
+
+		retryBackoff: BackoffExponentialWithJitter(50*time.Millisecond, 0.2),
+		retryCodes:   []codes.Code{codes.Unavailable, codes.ResourceExhausted, codes.Aborted},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func defaultLevelFunc(code codes.Code) zapcore.Level {
+	if code == codes.OK {
+		return zapcore.InfoLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+// WithLogger sets the zap.Logger used by the interceptor. Defaults to
+// zap.NewNop() if never set.
+func WithLogger(logger *zap.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithLevelFunc overrides how a finished call's status code maps to a zap
+// level, e.g. to log codes.NotFound at Info instead of Error.
+func WithLevelFunc(f LevelFunc) Option {
+	return func(o *options) { o.levelFunc = f }
+}
+
+// WithDurationField toggles whether the call duration is attached to log
+// entries. Enabled by default.
+func WithDurationField(enabled bool) Option {
+	return func(o *options) { o.logDuration = enabled }
+}
+
+// WithFieldExtractor attaches additional zap fields derived from the request
+// context to every log entry.
+func WithFieldExtractor(f FieldExtractorFunc) Option {
+	return func(o *options) { o.fieldExtractor = f }
+}
+
+// WithSkipper suppresses logging for calls the skipper returns true for,
+// e.g. health checks.
+func WithSkipper(f SkipperFunc) Option {
+	return func(o *options) { o.skipper = f }
+}
+
+// WithPayloadLogging makes the logging interceptors attach the request and
+// response messages (marshaled as proto JSON) to the log entry. Disabled by
+// default since payloads can be large or contain sensitive data.
+func WithPayloadLogging() Option {
+	return func(o *options) { o.payloadLogging = true }
+}
+
+// WithPanicCounter increments c every time the recovery interceptors
+// recover a panic, for alerting on panic rate independent of log parsing.
+func WithPanicCounter(c prometheus.Counter) Option {
+	return func(o *options) { o.panicCounter = c }
+}
+
+// logAt logs msg at level if it's enabled for logger, mirroring the
+// zap.Logger.Check pattern used to avoid building fields for disabled
+// levels.
+func logAt(logger *zap.Logger, level zapcore.Level, msg string, fields ...zap.Field) {
+	if ce := logger.Check(level, msg); ce != nil {
+		ce.Write(fields...)
+	}
+}
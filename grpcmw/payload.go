@@ -0,0 +1,27 @@
+package grpcmw
+
+import (
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// payloadFields marshals req and resp as proto JSON for attachment to a log
+// entry. Values that aren't proto.Message (e.g. nil, or a failed call's zero
+// reply) are silently omitted.
+func payloadFields(req, resp interface{}) []zap.Field {
+	var fields []zap.Field
+
+	if m, ok := req.(proto.Message); ok {
+		if b, err := protojson.Marshal(m); err == nil {
+			fields = append(fields, zap.String("grpc.request.content", string(b)))
+		}
+	}
+	if m, ok := resp.(proto.Message); ok {
+		if b, err := protojson.Marshal(m); err == nil {
+			fields = append(fields, zap.String("grpc.response.content", string(b)))
+		}
+	}
+
+	return fields
+}
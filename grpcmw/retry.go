@@ -0,0 +1,358 @@
+package grpcmw
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// BackoffFunc computes how long to wait before retry attempt n, where
+// attempt 0 is the wait before the first retry (i.e. after the initial call
+// has already failed once).
+type BackoffFunc func(attempt uint) time.Duration
+
+// maxBackoff bounds the wait computed by BackoffExponential and
+// BackoffExponentialWithJitter so a high attempt count can't block a caller
+// for an unreasonable amount of time.
+const maxBackoff = 30 * time.Second
+
+// BackoffLinear waits a fixed duration d between every retry attempt.
+func BackoffLinear(d time.Duration) BackoffFunc {
+	return func(attempt uint) time.Duration {
+		return d
+	}
+}
+
+// BackoffExponential waits base*2^attempt between retries, capped at
+// maxBackoff.
+func BackoffExponential(base time.Duration) BackoffFunc {
+	return func(attempt uint) time.Duration {
+		wait := base * time.Duration(math.Pow(2, float64(attempt)))
+		if wait <= 0 || wait > maxBackoff {
+			wait = maxBackoff
+		}
+		return wait
+	}
+}
+
+// BackoffExponentialWithJitter behaves like BackoffExponential but perturbs
+// the wait by up to +/- jitterFraction of its value, to keep retrying
+// clients from all retrying in lockstep against a recovering server.
+func BackoffExponentialWithJitter(base time.Duration, jitterFraction float64) BackoffFunc {
+	exp := BackoffExponential(base)
+	return func(attempt uint) time.Duration {
+		wait := exp(attempt)
+		jitter := 1 + jitterFraction*(rand.Float64()*2-1)
+		return time.Duration(float64(wait) * jitter)
+	}
+}
+
+// WithMax sets the maximum number of retry attempts after the initial call.
+// Zero (the default) disables retrying.
+func WithMax(n uint) Option {
+	return func(o *options) { o.retryMax = n }
+}
+
+// WithPerRetryTimeout bounds each individual unary call attempt with its own
+// context.WithTimeout, independent of the parent context's deadline.
+func WithPerRetryTimeout(d time.Duration) Option {
+	return func(o *options) { o.retryPerCallTimeout = d }
+}
+
+// WithBackoff overrides how long to wait between retry attempts.
+func WithBackoff(f BackoffFunc) Option {
+	return func(o *options) { o.retryBackoff = f }
+}
+
+// WithCodes overrides the set of status codes that are considered retriable.
+// Defaults to Unavailable, ResourceExhausted, and Aborted.
+func WithCodes(cs ...codes.Code) Option {
+	return func(o *options) { o.retryCodes = cs }
+}
+
+// retryCallOption carries per-call Option overrides (e.g. WithMax,
+// WithCodes) through the standard grpc.CallOption mechanism, so a single RPC
+// can tighten or loosen retry behavior without a dedicated interceptor.
+type retryCallOption struct {
+	grpc.EmptyCallOption
+	opts []Option
+}
+
+// RetryCallOptions bundles Option overrides into a grpc.CallOption honored
+// by UnaryClientRetry/StreamClientRetry for that call only, e.g.
+// client.Foo(ctx, req, grpcmw.RetryCallOptions(grpcmw.WithMax(1))).
+func RetryCallOptions(opts ...Option) grpc.CallOption {
+	return retryCallOption{opts: opts}
+}
+
+// splitRetryCallOptions pulls retryCallOption entries out of callOpts,
+// applying them to a copy of base, and returns the effective options plus
+// the remaining call options the real invoker understands.
+func splitRetryCallOptions(base *options, callOpts []grpc.CallOption) (*options, []grpc.CallOption) {
+	effective := base
+	remaining := make([]grpc.CallOption, 0, len(callOpts))
+	var overrides []Option
+
+	for _, opt := range callOpts {
+		if co, ok := opt.(retryCallOption); ok {
+			overrides = append(overrides, co.opts...)
+			continue
+		}
+		remaining = append(remaining, opt)
+	}
+
+	if len(overrides) > 0 {
+		copied := *base
+		effective = &copied
+		for _, opt := range overrides {
+			opt(effective)
+		}
+	}
+
+	return effective, remaining
+}
+
+func grpcCodeOf(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return codes.Unknown
+	}
+	return st.Code()
+}
+
+func isRetriableCode(err error, retriable []codes.Code) bool {
+	code := grpcCodeOf(err)
+	for _, c := range retriable {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryClientRetry returns a gRPC unary client interceptor that retries
+// failed calls whose status code is in the configured set (see WithCodes),
+// using the configured backoff (see WithBackoff) between attempts. Disabled
+// (a single attempt, no retries) unless WithMax is set to a nonzero value.
+func UnaryClientRetry(opts ...Option) grpc.UnaryClientInterceptor {
+	base := newOptions(opts...)
+
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		callOpts ...grpc.CallOption,
+	) error {
+		o, callOpts := splitRetryCallOptions(base, callOpts)
+
+		if o.retryMax == 0 {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		}
+
+		var lastErr error
+		for attempt := uint(0); ; attempt++ {
+			if attempt > 0 {
+				wait := o.retryBackoff(attempt - 1)
+				select {
+				case <-ctx.Done():
+					return lastErr
+				case <-time.After(wait):
+				}
+
+				o.logger.Info("retrying grpc call",
+					zap.String("grpc_method", method),
+					zap.Uint("attempt", attempt),
+					zap.String("grpc_code", grpcCodeOf(lastErr).String()),
+					zap.Duration("backoff", wait),
+				)
+			}
+
+			callCtx := ctx
+			if o.retryPerCallTimeout > 0 {
+				var cancel context.CancelFunc
+				callCtx, cancel = context.WithTimeout(ctx, o.retryPerCallTimeout)
+				lastErr = invoker(callCtx, method, req, reply, cc, callOpts...)
+				cancel()
+			} else {
+				lastErr = invoker(callCtx, method, req, reply, cc, callOpts...)
+			}
+
+			if lastErr == nil || attempt >= o.retryMax || !isRetriableCode(lastErr, o.retryCodes) {
+				return lastErr
+			}
+		}
+	}
+}
+
+// StreamClientRetry returns a gRPC streaming client interceptor that retries
+// a stream that fails before any message has been received on it, honoring
+// the same WithMax/WithCodes/WithBackoff configuration as UnaryClientRetry.
+// A stream that has already delivered at least one message is never
+// retried, since the caller may have already acted on it.
+//
+// Only server-streaming RPCs (desc.ClientStreams == false) are retried: a
+// retry re-establishes the stream from scratch and replays the single
+// request message the caller sent before reading began. Client-streaming and
+// bidirectional RPCs can have an arbitrary number of client messages
+// in flight by the time a failure is observed, which can't be safely
+// replayed, so retries are disabled for them and the call proceeds as if
+// WithMax were 0.
+func StreamClientRetry(opts ...Option) grpc.StreamClientInterceptor {
+	base := newOptions(opts...)
+
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		callOpts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		o, callOpts := splitRetryCallOptions(base, callOpts)
+
+		newStream := func() (grpc.ClientStream, error) {
+			return streamer(ctx, desc, cc, method, callOpts...)
+		}
+
+		if o.retryMax == 0 {
+			return newStream()
+		}
+
+		if desc.ClientStreams {
+			o.logger.Warn("grpc stream retry is only supported for server-streaming RPCs; "+
+				"retries are disabled for this call",
+				zap.String("grpc_method", method),
+			)
+			return newStream()
+		}
+
+		cs, err := newStream()
+		if err != nil {
+			return nil, err
+		}
+
+		return &retryingClientStream{
+			ClientStream: cs,
+			ctx:          ctx,
+			method:       method,
+			o:            o,
+			newStream:    newStream,
+		}, nil
+	}
+}
+
+// retryingClientStream wraps a grpc.ClientStream so that a failure on its
+// first RecvMsg transparently re-establishes the stream and retries,
+// instead of surfacing the error to the caller. Every method is serialized
+// through mu, since ClientStream swaps on retry and the embedded
+// grpc.ClientStream is otherwise read/written without synchronization by the
+// promoted methods.
+type retryingClientStream struct {
+	grpc.ClientStream
+
+	mu           sync.Mutex
+	receivedGood bool
+	attempt      uint
+	sent         []interface{}
+
+	ctx       context.Context
+	method    string
+	o         *options
+	newStream func() (grpc.ClientStream, error)
+}
+
+func (s *retryingClientStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ClientStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func (s *retryingClientStream) CloseSend() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ClientStream.CloseSend()
+}
+
+func (s *retryingClientStream) Header() (metadata.MD, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ClientStream.Header()
+}
+
+func (s *retryingClientStream) Trailer() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ClientStream.Trailer()
+}
+
+func (s *retryingClientStream) RecvMsg(m interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.ClientStream.RecvMsg(m)
+	for err != nil && !s.receivedGood && s.attempt < s.o.retryMax && isRetriableCode(err, s.o.retryCodes) {
+		s.attempt++
+		wait := s.o.retryBackoff(s.attempt - 1)
+
+		select {
+		case <-s.ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+
+		s.o.logger.Info("retrying grpc stream",
+			zap.String("grpc_method", s.method),
+			zap.Uint("attempt", s.attempt),
+			zap.String("grpc_code", grpcCodeOf(err).String()),
+			zap.Duration("backoff", wait),
+		)
+
+		cs, newErr := s.newStream()
+		if newErr != nil {
+			err = newErr
+			continue
+		}
+		if replayErr := s.replaySent(cs); replayErr != nil {
+			err = replayErr
+			continue
+		}
+		s.ClientStream = cs
+		err = s.ClientStream.RecvMsg(m)
+	}
+
+	if err == nil {
+		s.receivedGood = true
+	}
+	return err
+}
+
+// replaySent resends every message previously accepted by SendMsg (and
+// closes the send side again) against a freshly established stream, so a
+// retry doesn't leave the new stream waiting on a request the server never
+// saw.
+func (s *retryingClientStream) replaySent(cs grpc.ClientStream) error {
+	for _, m := range s.sent {
+		if err := cs.SendMsg(m); err != nil {
+			return err
+		}
+	}
+	return cs.CloseSend()
+}
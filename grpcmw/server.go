@@ -0,0 +1,227 @@
+package grpcmw
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/technothecow/termine-go-utils/grpcmw/ctxzap"
+	"github.com/technothecow/termine-go-utils/internal/stacktrace"
+	"github.com/technothecow/termine-go-utils/tracing"
+)
+
+// RecoveryHandlerFunc translates a recovered panic value into the error
+// returned to the caller. The default returns a generic codes.Internal
+// status.
+type RecoveryHandlerFunc func(ctx context.Context, p interface{}) error
+
+func defaultRecoveryHandler(_ context.Context, p interface{}) error {
+	return status.Errorf(codes.Internal, "internal server panic: %v", p)
+}
+
+// WithRecoveryHandler overrides how a recovered panic is translated into the
+// error returned to the caller. Only honored by the server-side recovery
+// interceptors.
+func WithRecoveryHandler(f RecoveryHandlerFunc) Option {
+	return func(o *options) { o.recoveryHandler = f }
+}
+
+// UnaryServerLogging returns a gRPC unary server interceptor that logs
+// incoming requests using zap, and attaches logger to the handler's context
+// so it can be retrieved with ctxzap.Extract.
+//
+// Fields:
+//   - grpc.service
+//   - grpc.method
+//   - grpc_code
+//   - duration (unless WithDurationField(false))
+//   - peer.address (when available)
+//   - grpc.deadline (when the call has a deadline)
+//   - error (when non-nil)
+func UnaryServerLogging(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts...)
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if o.skipper != nil && o.skipper(ctx, info.FullMethod) {
+			ctx = ctxzap.ToContext(ctx, o.logger)
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		ctx = ctxzap.ToContext(ctx, o.logger)
+
+		resp, err := handler(ctx, req)
+
+		fields, code := serverCallFields(ctx, info.FullMethod, start, err, o)
+		if o.payloadLogging {
+			fields = append(fields, payloadFields(req, resp)...)
+		}
+
+		msg := "grpc server call"
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+			msg = "grpc server call failed"
+		}
+		logAt(o.logger, o.levelFunc(code), msg, fields...)
+
+		return resp, err
+	}
+}
+
+// StreamServerLogging returns a gRPC streaming server interceptor that logs
+// incoming streaming RPCs using zap, and attaches logger to the handler's
+// context so it can be retrieved with ctxzap.Extract.
+func StreamServerLogging(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts...)
+
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx := ctxzap.ToContext(ss.Context(), o.logger)
+		wrapped := &loggingServerStream{ServerStream: ss, ctx: ctx}
+
+		if o.skipper != nil && o.skipper(ctx, info.FullMethod) {
+			return handler(srv, wrapped)
+		}
+
+		start := time.Now()
+		err := handler(srv, wrapped)
+
+		fields, code := serverCallFields(ctx, info.FullMethod, start, err, o)
+
+		msg := "grpc server stream"
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+			msg = "grpc server stream failed"
+		}
+		logAt(o.logger, o.levelFunc(code), msg, fields...)
+
+		return err
+	}
+}
+
+// UnaryServerRecovery returns a gRPC unary server interceptor that converts
+// panics into gRPC errors (via RecoveryHandlerFunc) and logs them with stack
+// traces.
+func UnaryServerRecovery(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts...)
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				o.logger.Error("panic recovered in grpc unary server",
+					zap.String("grpc.method", info.FullMethod),
+					zap.Any("panic", r),
+					stacktrace.Field(4),
+				)
+				if o.panicCounter != nil {
+					o.panicCounter.Inc()
+				}
+				err = o.recoveryHandler(ctx, r)
+				resp = nil
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecovery returns a gRPC streaming server interceptor that
+// converts panics into gRPC errors (via RecoveryHandlerFunc) and logs them
+// with stack traces.
+func StreamServerRecovery(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts...)
+
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				o.logger.Error("panic recovered in grpc stream server",
+					zap.String("grpc.method", info.FullMethod),
+					zap.Any("panic", r),
+					stacktrace.Field(4),
+				)
+				if o.panicCounter != nil {
+					o.panicCounter.Inc()
+				}
+				err = o.recoveryHandler(ss.Context(), r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// loggingServerStream overrides ServerStream.Context so that handlers see
+// the logger-carrying context built by StreamServerLogging.
+type loggingServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *loggingServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func serverCallFields(ctx context.Context, fullMethod string, start time.Time, err error, o *options) ([]zap.Field, codes.Code) {
+	service, method := splitMethodName(fullMethod)
+
+	fields := []zap.Field{
+		zap.String("grpc.service", service),
+		zap.String("grpc.method", method),
+	}
+	if o.logDuration {
+		fields = append(fields, zap.Duration("duration", time.Since(start)))
+	}
+	if o.fieldExtractor != nil {
+		fields = append(fields, o.fieldExtractor(ctx)...)
+	}
+	fields = append(fields, tracing.SpanFields(ctx)...)
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, zap.String("peer.address", p.Addr.String()))
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		fields = append(fields, zap.Time("grpc.deadline", deadline))
+	}
+
+	st, _ := status.FromError(err)
+	code := codes.OK
+	if st != nil {
+		code = st.Code()
+	}
+	fields = append(fields, zap.String("grpc_code", code.String()))
+
+	return fields, code
+}
+
+func splitMethodName(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.Index(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
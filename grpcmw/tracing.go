@@ -0,0 +1,178 @@
+package grpcmw
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/technothecow/termine-go-utils/tracing"
+)
+
+const instrumentationName = "github.com/technothecow/termine-go-utils/grpcmw"
+
+// UnaryClientTracing returns a gRPC unary client interceptor that starts a
+// span named after the gRPC method, propagates it to the server via W3C
+// traceparent metadata, and records the call's outcome on the span.
+func UnaryClientTracing(tp trace.TracerProvider, opts ...tracing.Option) grpc.UnaryClientInterceptor {
+	to := tracing.NewOptions(tp, opts...)
+	tracer := to.TracerProvider.Tracer(instrumentationName)
+
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		callOpts ...grpc.CallOption,
+	) error {
+		ctx, span := tracer.Start(ctx, strings.TrimPrefix(method, "/"), trace.WithSpanKind(trace.SpanKindClient))
+		defer span.End()
+
+		ctx = injectOutgoingTraceContext(ctx, to)
+
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		recordGRPCOutcome(span, err)
+		return err
+	}
+}
+
+// StreamClientTracing returns a gRPC streaming client interceptor that
+// starts a span named after the gRPC method, propagates it to the server via
+// W3C traceparent metadata, and records the stream's outcome on the span
+// once it completes (its first error, including a clean io.EOF).
+func StreamClientTracing(tp trace.TracerProvider, opts ...tracing.Option) grpc.StreamClientInterceptor {
+	to := tracing.NewOptions(tp, opts...)
+	tracer := to.TracerProvider.Tracer(instrumentationName)
+
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		callOpts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		ctx, span := tracer.Start(ctx, strings.TrimPrefix(method, "/"), trace.WithSpanKind(trace.SpanKindClient))
+		ctx = injectOutgoingTraceContext(ctx, to)
+
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			recordGRPCOutcome(span, err)
+			span.End()
+			return nil, err
+		}
+
+		return &tracingClientStream{ClientStream: cs, span: span}, nil
+	}
+}
+
+// UnaryServerTracing returns a gRPC unary server interceptor that extracts
+// any propagated trace context from incoming metadata, starts a span named
+// after the gRPC method, and records the call's outcome on the span.
+func UnaryServerTracing(tp trace.TracerProvider, opts ...tracing.Option) grpc.UnaryServerInterceptor {
+	to := tracing.NewOptions(tp, opts...)
+	tracer := to.TracerProvider.Tracer(instrumentationName)
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx = extractIncomingTraceContext(ctx, to)
+		ctx, span := tracer.Start(ctx, strings.TrimPrefix(info.FullMethod, "/"), trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		recordGRPCOutcome(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerTracing returns a gRPC streaming server interceptor that
+// extracts any propagated trace context from incoming metadata, starts a
+// span named after the gRPC method, and records the stream's outcome on the
+// span.
+func StreamServerTracing(tp trace.TracerProvider, opts ...tracing.Option) grpc.StreamServerInterceptor {
+	to := tracing.NewOptions(tp, opts...)
+	tracer := to.TracerProvider.Tracer(instrumentationName)
+
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx := extractIncomingTraceContext(ss.Context(), to)
+		ctx, span := tracer.Start(ctx, strings.TrimPrefix(info.FullMethod, "/"), trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		err := handler(srv, &loggingServerStream{ServerStream: ss, ctx: ctx})
+		recordGRPCOutcome(span, err)
+		return err
+	}
+}
+
+func injectOutgoingTraceContext(ctx context.Context, to *tracing.Options) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	to.Propagator.Inject(ctx, tracing.MetadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func extractIncomingTraceContext(ctx context.Context, to *tracing.Options) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return to.Propagator.Extract(ctx, tracing.MetadataCarrier(md))
+}
+
+// recordGRPCOutcome records a finished call's status on span as
+// rpc.grpc.status_code/otel.status_code attributes, plus the error itself
+// as a span event when non-nil.
+func recordGRPCOutcome(span trace.Span, err error) {
+	st, _ := status.FromError(err)
+	code := codes.OK
+	if st != nil {
+		code = st.Code()
+	}
+	span.SetAttributes(attribute.Int64("rpc.grpc.status_code", int64(code)))
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		span.SetAttributes(attribute.String("otel.status_code", "ERROR"))
+		return
+	}
+	span.SetAttributes(attribute.String("otel.status_code", "OK"))
+}
+
+// tracingClientStream ends and annotates span once the wrapped stream
+// reaches its first terminal RecvMsg (an error, or a clean io.EOF).
+type tracingClientStream struct {
+	grpc.ClientStream
+	span trace.Span
+}
+
+func (s *tracingClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		if err == io.EOF {
+			recordGRPCOutcome(s.span, nil)
+		} else {
+			recordGRPCOutcome(s.span, err)
+		}
+		s.span.End()
+	}
+	return err
+}
@@ -0,0 +1,16 @@
+package httpmw
+
+import "github.com/labstack/echo/v4"
+
+// Chain composes multiple Echo middleware into a single one, invoked
+// outer-to-inner in the order passed in, i.e. Chain(a, b, c) wraps a handler
+// as a(b(c(handler))).
+func Chain(middlewares ...echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		h := next
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			h = middlewares[i](h)
+		}
+		return h
+	}
+}
@@ -1,82 +1,113 @@
 package httpmw
 
 import (
+	"bytes"
 	"time"
 
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/zap"
+
+	"github.com/technothecow/termine-go-utils/internal/stacktrace"
+	"github.com/technothecow/termine-go-utils/tracing"
 )
 
 // Logging returns an Echo middleware that logs incoming HTTP requests using
-// the provided zap.Logger.
+// zap.
 //
 // Fields:
 //   - method
 //   - path
 //   - status
-//   - latency
+//   - latency (unless WithDurationField(false))
 //   - remote_ip
 //   - user_agent
 //   - request_id
 //   - error (only when non-nil)
-func Logging(logger *zap.Logger) echo.MiddlewareFunc {
-	if logger == nil {
-		logger = zap.NewNop()
-	}
+func Logging(opts ...Option) echo.MiddlewareFunc {
+	o := newOptions(opts...)
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			if o.skipper(c) {
+				return next(c)
+			}
+
 			req := c.Request()
 			res := c.Response()
 
+			var reqBody []byte
+			var dumper *bodyDumpResponseWriter
+			if o.payloadLogging {
+				reqBody = capturedRequestBody(c)
+				dumper = &bodyDumpResponseWriter{ResponseWriter: res.Writer, buf: &bytes.Buffer{}}
+				res.Writer = dumper
+			}
+
 			start := time.Now()
 			err := next(c)
-			stop := time.Now()
-
-			latency := stop.Sub(start)
 
 			fields := []zap.Field{
 				zap.String("method", req.Method),
 				zap.String("path", req.URL.Path),
 				zap.Int("status", res.Status),
-				zap.Duration("latency", latency),
 				zap.String("remote_ip", c.RealIP()),
 				zap.String("user_agent", req.UserAgent()),
 				zap.String("request_id", req.Header.Get(echo.HeaderXRequestID)),
 			}
+			if o.logDuration {
+				fields = append(fields, zap.Duration("latency", time.Since(start)))
+			}
+			if o.fieldExtractor != nil {
+				fields = append(fields, o.fieldExtractor(c)...)
+			}
+			// Re-read the request in case an earlier middleware (e.g.
+			// Tracing) replaced it with one carrying an updated context.
+			fields = append(fields, tracing.SpanFields(c.Request().Context())...)
+			if dumper != nil {
+				fields = append(fields, payloadFields(reqBody, dumper.buf.Bytes())...)
+			}
 
+			msg := "http request"
 			if err != nil {
 				// Ensure Echo's HTTP error handling still runs.
 				c.Error(err)
 				fields = append(fields, zap.Error(err))
-				logger.Error("http request failed", fields...)
-				return err
+				msg = "http request failed"
 			}
+			logAt(o.logger, o.levelFunc(res.Status), msg, fields...)
 
-			logger.Info("http request", fields...)
-			return nil
+			return err
 		}
 	}
 }
 
-// Recovery wraps Echo's Recover middleware and directs panic information
-// (including stack traces) into the provided zap.Logger.
-func Recovery(logger *zap.Logger) echo.MiddlewareFunc {
-	if logger == nil {
-		logger = zap.NewNop()
-	}
+// Recovery returns an Echo middleware that recovers panics in downstream
+// handlers, logs them with a structured stack trace, and converts them into
+// the error returned to the client via RecoveryHandlerFunc.
+func Recovery(opts ...Option) echo.MiddlewareFunc {
+	o := newOptions(opts...)
 
-	return middleware.RecoverWithConfig(middleware.RecoverConfig{
-		StackSize:         1 << 10, // 1KB
-		DisableStackAll:   false,
-		DisablePrintStack: true,
-		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
-			logger.Error("panic recovered in http handler",
-				zap.Error(err),
-				zap.ByteString("stack", stack),
-			)
-			return err
-		},
-	})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			if o.skipper(c) {
+				return next(c)
+			}
+
+			defer func() {
+				if r := recover(); r != nil {
+					o.logger.Error("panic recovered in http handler",
+						zap.String("path", c.Request().URL.Path),
+						zap.Any("panic", r),
+						stacktrace.Field(4),
+					)
+					if o.panicCounter != nil {
+						o.panicCounter.Inc()
+					}
+					err = o.recoveryHandler(c, r)
+				}
+			}()
+
+			return next(c)
+		}
+	}
 }
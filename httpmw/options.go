@@ -0,0 +1,137 @@
+package httpmw
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldExtractorFunc derives additional zap fields from the request, e.g.
+// to surface a value stashed in c by an earlier middleware.
+type FieldExtractorFunc func(c echo.Context) []zap.Field
+
+// LevelFunc maps a finished request's HTTP status code to the zap level it
+// should be logged at. The default logs 5xx at Error and everything else at
+// Info.
+type LevelFunc func(status int) zapcore.Level
+
+// RecoveryHandlerFunc translates a recovered panic value into the error
+// returned to the client. The default returns a generic 500 echo.HTTPError.
+type RecoveryHandlerFunc func(c echo.Context, p interface{}) error
+
+func defaultRecoveryHandler(_ echo.Context, p interface{}) error {
+	return echo.NewHTTPError(http.StatusInternalServerError, "internal server error").SetInternal(
+		&panicError{p},
+	)
+}
+
+// panicError adapts a recovered panic value to the error interface so it can
+// be attached to an echo.HTTPError's internal error.
+type panicError struct{ v interface{} }
+
+func (e *panicError) Error() string { return fmt.Sprintf("panic recovered: %v", e.v) }
+
+// Option configures the logging and recovery middleware in this package.
+type Option func(*options)
+
+type options struct {
+	logger          *zap.Logger
+	levelFunc       LevelFunc
+	logDuration     bool
+	fieldExtractor  FieldExtractorFunc
+	payloadLogging  bool
+	skipper         middleware.Skipper
+	recoveryHandler RecoveryHandlerFunc
+	panicCounter    prometheus.Counter
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		logger:          zap.NewNop(),
+		levelFunc:       defaultLevelFunc,
+		logDuration:     true,
+		skipper:         middleware.DefaultSkipper,
+		recoveryHandler: defaultRecoveryHandler,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func defaultLevelFunc(status int) zapcore.Level {
+	if status >= 500 {
+		return zapcore.ErrorLevel
+	}
+	return zapcore.InfoLevel
+}
+
+// WithLogger sets the zap.Logger used by the middleware. Defaults to
+// zap.NewNop() if never set.
+func WithLogger(logger *zap.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithLevelFunc overrides how a finished request's status code maps to a
+// zap level, e.g. to log 404s at Info instead of Error.
+func WithLevelFunc(f LevelFunc) Option {
+	return func(o *options) { o.levelFunc = f }
+}
+
+// WithDurationField toggles whether request latency is attached to log
+// entries. Enabled by default.
+func WithDurationField(enabled bool) Option {
+	return func(o *options) { o.logDuration = enabled }
+}
+
+// WithFieldExtractor attaches additional zap fields derived from the Echo
+// context to every log entry.
+func WithFieldExtractor(f FieldExtractorFunc) Option {
+	return func(o *options) { o.fieldExtractor = f }
+}
+
+// WithPayloadLogging makes Logging attach the request and response bodies to
+// the log entry. Disabled by default since payloads can be large or contain
+// sensitive data.
+func WithPayloadLogging() Option {
+	return func(o *options) { o.payloadLogging = true }
+}
+
+// WithSkipper suppresses instrumentation for requests the skipper returns
+// true for, e.g. health checks.
+func WithSkipper(skipper middleware.Skipper) Option {
+	return func(o *options) {
+		if skipper != nil {
+			o.skipper = skipper
+		}
+	}
+}
+
+// WithRecoveryHandler overrides how a recovered panic is translated into the
+// error returned to the client. Only honored by Recovery.
+func WithRecoveryHandler(f RecoveryHandlerFunc) Option {
+	return func(o *options) { o.recoveryHandler = f }
+}
+
+// WithPanicCounter increments c every time Recovery recovers a panic, for
+// alerting on panic rate independent of log parsing.
+func WithPanicCounter(c prometheus.Counter) Option {
+	return func(o *options) { o.panicCounter = c }
+}
+
+// logAt logs msg at level if it's enabled for logger, avoiding building
+// fields for a disabled level.
+func logAt(logger *zap.Logger, level zapcore.Level, msg string, fields ...zap.Field) {
+	if ce := logger.Check(level, msg); ce != nil {
+		ce.Write(fields...)
+	}
+}
@@ -0,0 +1,70 @@
+package httpmw
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// bodyDumpResponseWriter tees everything written to the response through to
+// buf so it can be attached to the log entry once the handler returns. Flush,
+// Hijack and Unwrap are passed through to the underlying ResponseWriter so
+// that streaming handlers (SSE, chunked transfer, WebSocket upgrades) keep
+// working with WithPayloadLogging() enabled.
+type bodyDumpResponseWriter struct {
+	http.ResponseWriter
+	buf *bytes.Buffer
+}
+
+func (w *bodyDumpResponseWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyDumpResponseWriter) Flush() {
+	err := http.NewResponseController(w.ResponseWriter).Flush()
+	if err != nil && errors.Is(err, http.ErrNotSupported) {
+		panic(errors.New("response writer flushing is not supported"))
+	}
+}
+
+func (w *bodyDumpResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *bodyDumpResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// capturedRequestBody reads the request body for logging and restores it so
+// downstream handlers can still read it.
+func capturedRequestBody(c echo.Context) []byte {
+	req := c.Request()
+	if req.Body == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body
+}
+
+func payloadFields(reqBody []byte, respBody []byte) []zap.Field {
+	var fields []zap.Field
+	if len(reqBody) > 0 {
+		fields = append(fields, zap.ByteString("http.request.body", reqBody))
+	}
+	if len(respBody) > 0 {
+		fields = append(fields, zap.ByteString("http.response.body", respBody))
+	}
+	return fields
+}
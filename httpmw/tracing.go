@@ -0,0 +1,52 @@
+package httpmw
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/technothecow/termine-go-utils/tracing"
+)
+
+const instrumentationName = "github.com/technothecow/termine-go-utils/httpmw"
+
+// Tracing returns an Echo middleware that extracts any propagated trace
+// context from incoming request headers, starts a span named "METHOD path"
+// (e.g. "GET /users/:id"), and records the response status on the span.
+func Tracing(tp trace.TracerProvider, opts ...tracing.Option) echo.MiddlewareFunc {
+	to := tracing.NewOptions(tp, opts...)
+	tracer := to.TracerProvider.Tracer(instrumentationName)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			ctx := to.Propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := tracer.Start(ctx, req.Method+" "+c.Path(), trace.WithSpanKind(trace.SpanKindServer))
+			defer span.End()
+
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+
+			status := c.Response().Status
+			span.SetAttributes(attribute.Int("http.status_code", status))
+
+			if err != nil || status >= 500 {
+				msg := ""
+				if err != nil {
+					span.RecordError(err)
+					msg = err.Error()
+				}
+				span.SetStatus(otelcodes.Error, msg)
+				span.SetAttributes(attribute.String("otel.status_code", "ERROR"))
+			} else {
+				span.SetAttributes(attribute.String("otel.status_code", "OK"))
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package stacktrace parses a recovered panic's call stack into structured
+// zap fields, shared by grpcmw and httpmw's recovery middleware so a panic
+// log entry can be queried by function/file/line instead of grepped out of a
+// raw byte blob.
+package stacktrace
+
+import (
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Frame is one entry of a parsed panic stack trace.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+func (f Frame) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("function", f.Function)
+	enc.AddString("file", f.File)
+	enc.AddInt("line", f.Line)
+	return nil
+}
+
+// Frames is a parsed panic stack trace, outermost frame first.
+type Frames []Frame
+
+func (fs Frames) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, f := range fs {
+		if err := enc.AppendObject(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Field captures the current goroutine's call stack as a structured "stack"
+// zap field of {function, file, line} records, instead of the raw bytes
+// debug.Stack() would produce. skip is passed directly to runtime.Callers,
+// so it's relative to runtime.Callers itself: 0 is Callers, 1 is Field, and
+// each additional value skips one more frame up the caller chain. When
+// called from a deferred recover() closure, that chain runs through
+// runtime.gopanic, so skip must account for Field's own frame, the deferred
+// closure, and runtime.gopanic to land on the function that actually
+// panicked.
+func Field(skip int) zap.Field {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	callerFrames := runtime.CallersFrames(pcs[:n])
+
+	var frames Frames
+	for {
+		frame, more := callerFrames.Next()
+		frames = append(frames, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+		if !more {
+			break
+		}
+	}
+
+	return zap.Array("stack", frames)
+}
@@ -0,0 +1,168 @@
+package metricsmw
+
+import (
+	"context"
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ClientMetrics holds the Prometheus collectors shared by UnaryClientMetrics
+// and StreamClientMetrics.
+type ClientMetrics struct {
+	startedTotal *prometheus.CounterVec
+	handledTotal *prometheus.CounterVec
+	msgReceived  *prometheus.CounterVec
+	msgSent      *prometheus.CounterVec
+}
+
+// NewClientMetrics creates and registers the collectors backing
+// UnaryClientMetrics and StreamClientMetrics: grpc_client_started_total,
+// grpc_client_handled_total{grpc_code}, grpc_client_msg_received_total, and
+// grpc_client_msg_sent_total, all labeled by grpc_type/grpc_service/grpc_method.
+func NewClientMetrics(opts ...Option) *ClientMetrics {
+	o := newOptions(opts...)
+
+	labels := []string{"grpc_type", "grpc_service", "grpc_method"}
+	m := &ClientMetrics{
+		startedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_started_total",
+			Help: "Total number of RPCs started on the client.",
+		}, labels),
+		handledTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_handled_total",
+			Help: "Total number of RPCs completed on the client, by status code.",
+		}, append(append([]string{}, labels...), "grpc_code")),
+		msgReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_msg_received_total",
+			Help: "Total number of gRPC messages received by the client.",
+		}, labels),
+		msgSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_msg_sent_total",
+			Help: "Total number of gRPC messages sent by the client.",
+		}, labels),
+	}
+	MustRegister(o.registerer, m.startedTotal, m.handledTotal, m.msgReceived, m.msgSent)
+
+	return m
+}
+
+// UnaryClientMetrics returns a gRPC unary client interceptor that records m
+// for every call.
+func UnaryClientMetrics(m *ClientMetrics) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		service, meth := splitMethodName(method)
+
+		m.startedTotal.WithLabelValues("unary", service, meth).Inc()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		if err == nil {
+			m.msgSent.WithLabelValues("unary", service, meth).Inc()
+			m.msgReceived.WithLabelValues("unary", service, meth).Inc()
+		}
+		m.handledTotal.WithLabelValues("unary", service, meth, grpcCodeOf(err).String()).Inc()
+
+		return err
+	}
+}
+
+// StreamClientMetrics returns a gRPC streaming client interceptor that
+// records m for every stream.
+func StreamClientMetrics(m *ClientMetrics) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		service, meth := splitMethodName(method)
+		grpcType := clientStreamType(desc)
+
+		m.startedTotal.WithLabelValues(grpcType, service, meth).Inc()
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			m.handledTotal.WithLabelValues(grpcType, service, meth, grpcCodeOf(err).String()).Inc()
+			return nil, err
+		}
+
+		return &metricsClientStream{
+			ClientStream: cs,
+			metrics:      m,
+			grpcType:     grpcType,
+			service:      service,
+			method:       meth,
+		}, nil
+	}
+}
+
+func clientStreamType(desc *grpc.StreamDesc) string {
+	switch {
+	case desc.ClientStreams && desc.ServerStreams:
+		return "bidi_stream"
+	case desc.ClientStreams:
+		return "client_stream"
+	case desc.ServerStreams:
+		return "server_stream"
+	default:
+		return "unary"
+	}
+}
+
+// metricsClientStream records message and completion metrics as the wrapped
+// stream is used, reporting grpc_client_handled_total exactly once, when
+// the first terminal RecvMsg (an error, or a clean io.EOF) is observed.
+type metricsClientStream struct {
+	grpc.ClientStream
+	metrics  *ClientMetrics
+	grpcType string
+	service  string
+	method   string
+	reported bool
+}
+
+func (s *metricsClientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.metrics.msgSent.WithLabelValues(s.grpcType, s.service, s.method).Inc()
+	}
+	return err
+}
+
+func (s *metricsClientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.metrics.msgReceived.WithLabelValues(s.grpcType, s.service, s.method).Inc()
+		return nil
+	}
+
+	if !s.reported {
+		s.reported = true
+		s.metrics.handledTotal.WithLabelValues(s.grpcType, s.service, s.method, grpcCodeOf(err).String()).Inc()
+	}
+	return err
+}
+
+func grpcCodeOf(err error) codes.Code {
+	if err == nil || err == io.EOF {
+		return codes.OK
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return codes.Unknown
+	}
+	return st.Code()
+}
@@ -0,0 +1,142 @@
+package metricsmw
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+)
+
+// ServerMetrics holds the Prometheus collectors shared by UnaryServerMetrics
+// and StreamServerMetrics.
+type ServerMetrics struct {
+	startedTotal *prometheus.CounterVec
+	handledTotal *prometheus.CounterVec
+	msgReceived  *prometheus.CounterVec
+	msgSent      *prometheus.CounterVec
+}
+
+// NewServerMetrics creates and registers the collectors backing
+// UnaryServerMetrics and StreamServerMetrics: grpc_server_started_total,
+// grpc_server_handled_total{grpc_code}, grpc_server_msg_received_total, and
+// grpc_server_msg_sent_total, all labeled by grpc_type/grpc_service/grpc_method.
+func NewServerMetrics(opts ...Option) *ServerMetrics {
+	o := newOptions(opts...)
+
+	labels := []string{"grpc_type", "grpc_service", "grpc_method"}
+	m := &ServerMetrics{
+		startedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_started_total",
+			Help: "Total number of RPCs started on the server.",
+		}, labels),
+		handledTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of RPCs completed on the server, by status code.",
+		}, append(append([]string{}, labels...), "grpc_code")),
+		msgReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_received_total",
+			Help: "Total number of gRPC messages received by the server.",
+		}, labels),
+		msgSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_msg_sent_total",
+			Help: "Total number of gRPC messages sent by the server.",
+		}, labels),
+	}
+	MustRegister(o.registerer, m.startedTotal, m.handledTotal, m.msgReceived, m.msgSent)
+
+	return m
+}
+
+// UnaryServerMetrics returns a gRPC unary server interceptor that records m
+// for every call.
+func UnaryServerMetrics(m *ServerMetrics) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		service, method := splitMethodName(info.FullMethod)
+
+		m.startedTotal.WithLabelValues("unary", service, method).Inc()
+		m.msgReceived.WithLabelValues("unary", service, method).Inc()
+
+		resp, err := handler(ctx, req)
+
+		if err == nil {
+			m.msgSent.WithLabelValues("unary", service, method).Inc()
+		}
+		m.handledTotal.WithLabelValues("unary", service, method, grpcCodeOf(err).String()).Inc()
+
+		return resp, err
+	}
+}
+
+// StreamServerMetrics returns a gRPC streaming server interceptor that
+// records m for every stream.
+func StreamServerMetrics(m *ServerMetrics) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		service, method := splitMethodName(info.FullMethod)
+		grpcType := serverStreamType(info)
+
+		m.startedTotal.WithLabelValues(grpcType, service, method).Inc()
+
+		wrapped := &metricsServerStream{
+			ServerStream: ss,
+			metrics:      m,
+			grpcType:     grpcType,
+			service:      service,
+			method:       method,
+		}
+		err := handler(srv, wrapped)
+
+		m.handledTotal.WithLabelValues(grpcType, service, method, grpcCodeOf(err).String()).Inc()
+
+		return err
+	}
+}
+
+func serverStreamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi_stream"
+	case info.IsClientStream:
+		return "client_stream"
+	case info.IsServerStream:
+		return "server_stream"
+	default:
+		return "unary"
+	}
+}
+
+// metricsServerStream records message metrics as the wrapped stream is
+// used. grpc_server_handled_total is recorded once, by StreamServerMetrics,
+// after the handler returns.
+type metricsServerStream struct {
+	grpc.ServerStream
+	metrics  *ServerMetrics
+	grpcType string
+	service  string
+	method   string
+}
+
+func (s *metricsServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.metrics.msgSent.WithLabelValues(s.grpcType, s.service, s.method).Inc()
+	}
+	return err
+}
+
+func (s *metricsServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.metrics.msgReceived.WithLabelValues(s.grpcType, s.service, s.method).Inc()
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package metricsmw
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcHarness wires a health.Server behind bufconn with the given metrics
+// interceptors installed on both ends, mirroring grpcmw's own bufconn test
+// helper.
+type grpcHarness struct {
+	client healthpb.HealthClient
+}
+
+func newGRPCHarness(t *testing.T, cm *ClientMetrics, sm *ServerMetrics) *grpcHarness {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerMetrics(sm)),
+		grpc.StreamInterceptor(StreamServerMetrics(sm)),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientMetrics(cm)),
+		grpc.WithStreamInterceptor(StreamClientMetrics(cm)),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &grpcHarness{client: healthpb.NewHealthClient(conn)}
+}
+
+func TestUnaryClientServerMetrics_SuccessfulCall(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cm := NewClientMetrics(WithRegisterer(reg))
+	sm := NewServerMetrics(WithRegisterer(prometheus.NewRegistry()))
+	h := newGRPCHarness(t, cm, sm)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := h.client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	if got := testutil.ToFloat64(cm.msgSent.WithLabelValues("unary", "grpc.health.v1.Health", "Check")); got != 1 {
+		t.Errorf("grpc_client_msg_sent_total = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(cm.msgReceived.WithLabelValues("unary", "grpc.health.v1.Health", "Check")); got != 1 {
+		t.Errorf("grpc_client_msg_received_total = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(cm.handledTotal.WithLabelValues("unary", "grpc.health.v1.Health", "Check", codes.OK.String())); got != 1 {
+		t.Errorf("grpc_client_handled_total{grpc_code=OK} = %v, want 1", got)
+	}
+}
+
+// TestUnaryClientMetrics_InvokerError verifies that when the invoker itself
+// fails (e.g. the RPC never reaches the server), grpc_client_msg_sent_total
+// and grpc_client_msg_received_total are not incremented even though
+// grpc_client_handled_total is.
+func TestUnaryClientMetrics_InvokerError(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cm := NewClientMetrics(WithRegisterer(reg))
+
+	interceptor := UnaryClientMetrics(cm)
+	wantErr := status.Error(codes.Unavailable, "connection refused")
+	failingInvoker := func(context.Context, string, interface{}, interface{}, *grpc.ClientConn, ...grpc.CallOption) error {
+		return wantErr
+	}
+
+	err := interceptor(context.Background(), "/svc/Method", nil, nil, nil, failingInvoker)
+	if err != wantErr {
+		t.Fatalf("interceptor returned %v, want %v", err, wantErr)
+	}
+
+	if got := testutil.ToFloat64(cm.msgSent.WithLabelValues("unary", "svc", "Method")); got != 0 {
+		t.Errorf("grpc_client_msg_sent_total after a failed call = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(cm.msgReceived.WithLabelValues("unary", "svc", "Method")); got != 0 {
+		t.Errorf("grpc_client_msg_received_total after a failed call = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(cm.handledTotal.WithLabelValues("unary", "svc", "Method", codes.Unavailable.String())); got != 1 {
+		t.Errorf("grpc_client_handled_total{grpc_code=Unavailable} = %v, want 1", got)
+	}
+}
+
+func TestStreamClientServerMetrics_SuccessfulWatch(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	cm := NewClientMetrics(WithRegisterer(reg))
+	sm := NewServerMetrics(WithRegisterer(prometheus.NewRegistry()))
+	h := newGRPCHarness(t, cm, sm)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ws, err := h.client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if _, err := ws.Recv(); err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+
+	if got := testutil.ToFloat64(cm.msgReceived.WithLabelValues("server_stream", "grpc.health.v1.Health", "Watch")); got != 1 {
+		t.Errorf("grpc_client_msg_received_total = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(cm.startedTotal.WithLabelValues("server_stream", "grpc.health.v1.Health", "Watch")); got != 1 {
+		t.Errorf("grpc_client_started_total = %v, want 1", got)
+	}
+}
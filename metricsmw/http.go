@@ -0,0 +1,65 @@
+package metricsmw
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HTTPMetrics holds the Prometheus collectors registered by Metrics.
+type HTTPMetrics struct {
+	requestsTotal   *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+}
+
+// NewHTTPMetrics creates and registers the collectors backing Metrics:
+// http_requests_total{method,path,status} and
+// http_request_duration_seconds{method,path,status}.
+func NewHTTPMetrics(opts ...Option) *HTTPMetrics {
+	o := newOptions(opts...)
+
+	m := &HTTPMetrics{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests handled.",
+		}, []string{"method", "path", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds.",
+			Buckets: o.durationBuckets,
+		}, []string{"method", "path", "status"}),
+	}
+	MustRegister(o.registerer, m.requestsTotal, m.requestDuration)
+
+	return m
+}
+
+// Metrics returns an Echo middleware that records m.requestsTotal and
+// m.requestDuration for every request. The path label is normalized via
+// WithPathNormalizer (defaulting to Echo's matched route, c.Path()) to keep
+// its cardinality bounded.
+func Metrics(m *HTTPMetrics, opts ...Option) echo.MiddlewareFunc {
+	o := newOptions(opts...)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// Ensure Echo's HTTP error handling (which writes the final
+				// status code) runs before we read c.Response().Status below.
+				c.Error(err)
+			}
+
+			path := o.pathNormalizer(c)
+			status := strconv.Itoa(c.Response().Status)
+
+			m.requestsTotal.WithLabelValues(c.Request().Method, path, status).Inc()
+			m.requestDuration.WithLabelValues(c.Request().Method, path, status).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package metricsmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_RecordsRequestsAndDuration(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewHTTPMetrics(WithRegisterer(reg))
+
+	e := echo.New()
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}, Metrics(m, WithRegisterer(reg)))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	got := testutil.ToFloat64(m.requestsTotal.WithLabelValues(http.MethodGet, "/users/:id", "200"))
+	if got != 1 {
+		t.Errorf("http_requests_total{method=GET,path=/users/:id,status=200} = %v, want 1", got)
+	}
+
+	if n := testutil.CollectAndCount(m.requestDuration); n != 1 {
+		t.Errorf("http_request_duration_seconds series count = %d, want 1", n)
+	}
+}
+
+func TestMetrics_PathNormalizerDefaultsToMatchedRoute(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewHTTPMetrics(WithRegisterer(reg))
+
+	e := echo.New()
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, Metrics(m))
+
+	for _, id := range []string{"1", "2", "3"} {
+		req := httptest.NewRequest(http.MethodGet, "/users/"+id, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+	}
+
+	got := testutil.ToFloat64(m.requestsTotal.WithLabelValues(http.MethodGet, "/users/:id", "200"))
+	if got != 3 {
+		t.Errorf("http_requests_total for the templated route = %v, want 3 (one series for all 3 concrete paths)", got)
+	}
+}
+
+// TestMetrics_RecordsErrorStatus verifies that a handler returning an
+// *echo.HTTPError is recorded under its actual status code rather than the
+// unwritten 200 default, which requires forcing Echo's error handler to run
+// (via c.Error) before the status is read.
+func TestMetrics_RecordsErrorStatus(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewHTTPMetrics(WithRegisterer(reg))
+
+	e := echo.New()
+	e.GET("/missing", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound, "not found")
+	}, Metrics(m, WithRegisterer(reg)))
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	if got := testutil.ToFloat64(m.requestsTotal.WithLabelValues(http.MethodGet, "/missing", "404")); got != 1 {
+		t.Errorf("http_requests_total{...,status=404} = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.requestsTotal.WithLabelValues(http.MethodGet, "/missing", "200")); got != 0 {
+		t.Errorf("http_requests_total{...,status=200} = %v, want 0 (error status must not be misrecorded as 200)", got)
+	}
+}
@@ -0,0 +1,74 @@
+// Package metricsmw registers and updates Prometheus collectors for HTTP
+// requests handled by Echo and gRPC calls made/handled through grpcmw,
+// mirroring the collector names used by prometheus/client_golang's own
+// examples and the grpc-ecosystem/go-grpc-prometheus package.
+package metricsmw
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PathNormalizer collapses a request's path into a lower-cardinality label
+// value, e.g. to turn "/users/42" into "/users/:id". Defaults to the route
+// path Echo matched (c.Path()), which is already templated.
+type PathNormalizer func(c echo.Context) string
+
+// Option configures the collectors registered by NewHTTPMetrics,
+// NewClientMetrics, and NewServerMetrics.
+type Option func(*options)
+
+type options struct {
+	registerer      prometheus.Registerer
+	durationBuckets []float64
+	pathNormalizer  PathNormalizer
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		registerer:      prometheus.DefaultRegisterer,
+		durationBuckets: prometheus.DefBuckets,
+		pathNormalizer:  func(c echo.Context) string { return c.Path() },
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithRegisterer sets the prometheus.Registerer collectors are registered
+// into. Defaults to prometheus.DefaultRegisterer.
+func WithRegisterer(r prometheus.Registerer) Option {
+	return func(o *options) { o.registerer = r }
+}
+
+// WithDurationBuckets overrides the histogram buckets used for
+// http_request_duration_seconds. Defaults to prometheus.DefBuckets.
+func WithDurationBuckets(buckets []float64) Option {
+	return func(o *options) { o.durationBuckets = buckets }
+}
+
+// WithPathNormalizer overrides how a request's path is turned into a label
+// value, e.g. to collapse "/users/42" into "/users/:id". Defaults to Echo's
+// own matched route path (c.Path()).
+func WithPathNormalizer(f PathNormalizer) Option {
+	return func(o *options) { o.pathNormalizer = f }
+}
+
+// MustRegister registers every collector in cs against registerer,
+// panicking if any is already registered or otherwise invalid.
+func MustRegister(registerer prometheus.Registerer, cs ...prometheus.Collector) {
+	for _, c := range cs {
+		registerer.MustRegister(c)
+	}
+}
+
+func splitMethodName(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.Index(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -0,0 +1,116 @@
+package tracing_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/technothecow/termine-go-utils/grpcmw"
+	"github.com/technothecow/termine-go-utils/httpmw"
+	"github.com/technothecow/termine-go-utils/tracing"
+)
+
+// TestTraceContextPropagation_HTTPToGRPC exercises the full chain this
+// package's interceptors are built for: an inbound HTTP request carrying a
+// traceparent header, extracted by httpmw.Tracing, whose handler then makes
+// an outbound gRPC call through a grpcmw-traced client/server pair. It
+// asserts every span exported along the way shares the root trace ID.
+func TestTraceContextPropagation_HTTPToGRPC(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	// otel.GetTextMapPropagator() defaults to a no-op, so every Tracing
+	// constructor below is given an explicit W3C propagator to match.
+	propagator := propagation.TraceContext{}
+	tracingOpt := tracing.WithPropagator(propagator)
+
+	// gRPC server: health.Health behind bufconn, wrapped with grpcmw server
+	// tracing so it extracts the context the handler below will propagate.
+	lis := bufconn.Listen(1024 * 1024)
+	grpcSrv := grpc.NewServer(grpc.UnaryInterceptor(grpcmw.UnaryServerTracing(tp, tracingOpt)))
+	healthpb.RegisterHealthServer(grpcSrv, health.NewServer())
+	go func() { _ = grpcSrv.Serve(lis) }()
+	t.Cleanup(grpcSrv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(grpcmw.UnaryClientTracing(tp, tracingOpt)),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	grpcClient := healthpb.NewHealthClient(conn)
+
+	// HTTP server: Echo wrapped with httpmw.Tracing, whose handler fans out
+	// to the gRPC server above using the context httpmw.Tracing set up.
+	e := echo.New()
+	e.Use(httpmw.Tracing(tp, tracingOpt))
+	e.GET("/check", func(c echo.Context) error {
+		ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+		defer cancel()
+		resp, err := grpcClient.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, resp.Status.String())
+	})
+	httpSrv := httptest.NewServer(e)
+	t.Cleanup(httpSrv.Close)
+
+	// Root span representing the original caller of the HTTP server, whose
+	// context is injected into the outgoing HTTP request headers.
+	rootCtx, rootSpan := tp.Tracer("test-client").Start(context.Background(), "root")
+	rootTraceID := rootSpan.SpanContext().TraceID()
+
+	req, err := http.NewRequestWithContext(rootCtx, http.MethodGet, httpSrv.URL+"/check", nil)
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	propagator.Inject(rootCtx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("http request: %v", err)
+	}
+	defer resp.Body.Close()
+	rootSpan.End()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	spans := exporter.GetSpans()
+	wantNames := map[string]bool{
+		"GET /check":                  false,
+		"grpc.health.v1.Health/Check": false,
+	}
+	for _, s := range spans {
+		if _, ok := wantNames[s.Name]; ok {
+			wantNames[s.Name] = true
+		}
+		if s.SpanContext.TraceID() != rootTraceID {
+			t.Errorf("span %q has trace ID %s, want %s (the root trace)", s.Name, s.SpanContext.TraceID(), rootTraceID)
+		}
+	}
+	for name, seen := range wantNames {
+		if !seen {
+			t.Errorf("expected a span named %q among the exported spans, got none", name)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package tracing
+
+import "google.golang.org/grpc/metadata"
+
+// MetadataCarrier adapts gRPC metadata.MD to propagation.TextMapCarrier so a
+// propagator can inject/extract trace context into/from gRPC metadata.
+type MetadataCarrier metadata.MD
+
+// Get returns the first value associated with key, or "" if absent.
+func (c MetadataCarrier) Get(key string) string {
+	vals := metadata.MD(c).Get(key)
+	if len(vals) == 0 {
+		return ""
+	}
+	return vals[0]
+}
+
+// Set replaces any existing values for key with value.
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys returns all the keys stored in the carrier.
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,61 @@
+// Package tracing holds the OpenTelemetry wiring shared by httpmw's and
+// grpcmw's Tracing constructors: a common way to resolve the TracerProvider
+// and propagator to use, and a helper to surface the active span's
+// identifiers as zap fields for log/span correlation.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Options holds the resolved TracerProvider and propagator a Tracing
+// constructor should use.
+type Options struct {
+	TracerProvider trace.TracerProvider
+	Propagator     propagation.TextMapPropagator
+}
+
+// Option overrides a field of Options away from its default.
+type Option func(*Options)
+
+// NewOptions resolves tp and opts against the OpenTelemetry globals
+// (otel.GetTracerProvider/otel.GetTextMapPropagator), so callers only need
+// to pass what they want to override.
+func NewOptions(tp trace.TracerProvider, opts ...Option) *Options {
+	o := &Options{
+		TracerProvider: tp,
+		Propagator:     otel.GetTextMapPropagator(),
+	}
+	if o.TracerProvider == nil {
+		o.TracerProvider = otel.GetTracerProvider()
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithPropagator overrides the propagator used to inject/extract trace
+// context across process boundaries. Defaults to otel.GetTextMapPropagator().
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(o *Options) { o.Propagator = p }
+}
+
+// SpanFields returns the zap fields used to correlate a log entry with the
+// span active on ctx: trace_id and span_id. It returns nil if ctx carries no
+// valid span context.
+func SpanFields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestProvider(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return tp, exporter
+}
+
+func TestSpanFields(t *testing.T) {
+	tp, exporter := newTestProvider(t)
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	fields := SpanFields(ctx)
+	span.End()
+
+	if len(exporter.GetSpans()) != 1 {
+		t.Fatalf("expected 1 exported span, got %d", len(exporter.GetSpans()))
+	}
+
+	sc := span.SpanContext()
+	want := map[string]string{
+		"trace_id": sc.TraceID().String(),
+		"span_id":  sc.SpanID().String(),
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("SpanFields returned %d fields, want %d", len(fields), len(want))
+	}
+	for _, f := range fields {
+		if got, ok := want[f.Key]; !ok || got != f.String {
+			t.Errorf("field %q = %q, want %q", f.Key, f.String, want[f.Key])
+		}
+	}
+}
+
+func TestSpanFields_NoActiveSpan(t *testing.T) {
+	if fields := SpanFields(context.Background()); fields != nil {
+		t.Errorf("SpanFields on a context with no span = %v, want nil", fields)
+	}
+}
+
+// TestMetadataCarrierPropagation exercises the full inject/extract round
+// trip through gRPC metadata using the standard W3C tracecontext
+// propagator, verifying a span started on the "server" side after
+// extraction shares the trace ID of the "client" span that was injected.
+func TestMetadataCarrierPropagation(t *testing.T) {
+	tp, exporter := newTestProvider(t)
+	propagator := propagation.TraceContext{}
+
+	clientCtx, clientSpan := tp.Tracer("client").Start(context.Background(), "outgoing-call")
+	defer clientSpan.End()
+
+	md := metadata.MD{}
+	propagator.Inject(clientCtx, MetadataCarrier(md))
+
+	if len(md.Get("traceparent")) == 0 {
+		t.Fatal("propagator.Inject did not set a traceparent header on MetadataCarrier")
+	}
+
+	serverCtx := propagator.Extract(context.Background(), MetadataCarrier(md))
+	serverCtx, serverSpan := tp.Tracer("server").Start(serverCtx, "incoming-call")
+	defer serverSpan.End()
+
+	clientTraceID := clientSpan.SpanContext().TraceID()
+	serverSC := trace.SpanContextFromContext(serverCtx)
+	if serverSC.TraceID() != clientTraceID {
+		t.Errorf("server span trace ID = %s, want %s (propagated from client span)", serverSC.TraceID(), clientTraceID)
+	}
+	if serverSC.SpanID() == clientSpan.SpanContext().SpanID() {
+		t.Error("server span ID should differ from the client span ID")
+	}
+
+	_ = exporter
+}
+
+func TestNewOptions_DefaultsAndOverride(t *testing.T) {
+	tp, _ := newTestProvider(t)
+
+	o := NewOptions(nil)
+	if o.TracerProvider == nil {
+		t.Error("NewOptions(nil).TracerProvider should fall back to the global provider, got nil")
+	}
+
+	custom := propagation.TraceContext{}
+	o = NewOptions(tp, WithPropagator(custom))
+	if o.TracerProvider != tp {
+		t.Error("NewOptions(tp) should use the passed-in TracerProvider")
+	}
+	if o.Propagator != custom {
+		t.Error("WithPropagator did not override the propagator")
+	}
+}